@@ -38,6 +38,9 @@ var (
 //
 // Even a slice of interface{} can be marshaled, provided that the type of every
 // value in the slice is the same, and each type implements Recorder.
+//
+// Marshal is a thin wrapper over Encoder using DefaultDialect; for large
+// inputs or a custom Dialect, use NewEncoder directly.
 func Marshal(v interface{}) ([]byte, error) {
 	vt := reflect.TypeOf(v)
 
@@ -48,7 +51,7 @@ func Marshal(v interface{}) ([]byte, error) {
 	}
 	if vt.Implements(recorderType) {
 		t := v.(Recorder)
-		return marshalRecorder(t), nil
+		return marshalRecorder(t)
 	}
 
 	// Any of the other checks only make sense on non-pointers.
@@ -71,17 +74,38 @@ func Marshal(v interface{}) ([]byte, error) {
 			return marshalInterfaceSlice(v)
 		}
 
+		if elemIsStruct(vt.Elem()) {
+			return marshalStructSlice(v)
+		}
+
 		return nil, fmt.Errorf("csv: slice element type %s does not implement Recorder", vt.Elem())
 	}
 
+	if vt.Kind() == reflect.Struct {
+		return marshalStruct(v)
+	}
+
 	return nil, fmt.Errorf("csv: cannot marshal %s", vt)
 }
 
-func marshalRecorder(v Recorder) []byte {
+// elemIsStruct reports whether t, or the type t points to, is a struct.
+func elemIsStruct(t reflect.Type) bool {
+	if t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	return t.Kind() == reflect.Struct
+}
+
+func marshalRecorder(v Recorder) ([]byte, error) {
 	var buf bytes.Buffer
-	writeRecord(&buf, v.Header())
-	writeRecord(&buf, v.Record())
-	return buf.Bytes()
+	enc := NewEncoder(&buf)
+	if err := enc.Encode(v); err != nil {
+		return nil, err
+	}
+	if err := enc.Flush(); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
 }
 
 func marshalRecorderSlice(v interface{}) ([]byte, error) {
@@ -96,9 +120,14 @@ func marshalRecorderSlice(v interface{}) ([]byte, error) {
 	}
 
 	var buf bytes.Buffer
-	writeRecord(&buf, get(0).Header())
+	enc := NewEncoder(&buf)
 	for i := 0; i < n; i++ {
-		writeRecord(&buf, get(i).Record())
+		if err := enc.Encode(get(i)); err != nil {
+			return nil, err
+		}
+	}
+	if err := enc.Flush(); err != nil {
+		return nil, err
 	}
 	return buf.Bytes(), nil
 }
@@ -137,19 +166,14 @@ func marshalInterfaceSlice(v interface{}) (bs []byte, err error) {
 	}()
 
 	var buf bytes.Buffer
-	writeRecord(&buf, get(0).Header())
+	enc := NewEncoder(&buf)
 	for i := 0; i < n; i++ {
-		writeRecord(&buf, get(i).Record())
+		if err := enc.Encode(get(i)); err != nil {
+			return nil, err
+		}
 	}
-	return buf.Bytes(), nil
-}
-
-func writeRecord(buf *bytes.Buffer, slice []string) {
-	m := len(slice) - 1
-	for _, s := range slice[:m] {
-		buf.WriteString(s)
-		buf.WriteRune(',')
+	if err := enc.Flush(); err != nil {
+		return nil, err
 	}
-	buf.WriteString(slice[m])
-	buf.WriteRune('\n')
+	return buf.Bytes(), nil
 }
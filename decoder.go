@@ -0,0 +1,344 @@
+// Copyright (c) 2016, Ben Morgan. All rights reserved.
+// Use of this source code is governed by an MIT license
+// that can be found in the LICENSE file.
+
+package csv
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"io"
+	"reflect"
+)
+
+// An Unmarshaler specifies directly how the data is unmarshaled from CSV.
+type Unmarshaler interface {
+	UnmarshalCSV([]byte) error
+}
+
+// A RecordSetter is the decoding counterpart to Recorder. SetHeader is
+// called once with the header row, and SetRecord is called once per data
+// row, so implementations can map columns either positionally or by name.
+type RecordSetter interface {
+	SetHeader([]string) error
+	SetRecord([]string) error
+}
+
+var (
+	unmarshalerType  = reflect.TypeOf(new(Unmarshaler)).Elem()
+	recordSetterType = reflect.TypeOf(new(RecordSetter)).Elem()
+)
+
+// Unmarshal parses CSV data and stores the result in v, which must be a
+// pointer to a type that implements Unmarshaler or RecordSetter, a pointer
+// to a struct, or a pointer to a slice of RecordSetter or struct values (or
+// of pointers to them). A plain struct destination is matched against the header using
+// its "csv" struct tags; see the package documentation on struct tags for
+// details.
+//
+// For a slice destination, the header row is parsed once and passed to
+// every element: one element is allocated per data row, then either
+// SetHeader/SetRecord is called on it with the shared header, or its
+// struct fields are populated directly.
+//
+// Except for an Unmarshaler, which receives data unchanged, Unmarshal
+// tokenizes data under DefaultLimits, returning a *LimitError if the input
+// exceeds them; use UnmarshalWithLimits to override them, or a
+// Decoder directly for full control.
+func Unmarshal(data []byte, v interface{}) error {
+	return UnmarshalWithLimits(data, v, DefaultLimits())
+}
+
+// UnmarshalWithLimits is like Unmarshal, but tokenizes data under limits
+// instead of DefaultLimits.
+func UnmarshalWithLimits(data []byte, v interface{}, limits Limits) error {
+	vt := reflect.TypeOf(v)
+	if vt == nil || vt.Kind() != reflect.Ptr {
+		return fmt.Errorf("csv: Unmarshal requires a pointer, got %s", vt)
+	}
+
+	if vt.Implements(unmarshalerType) {
+		return v.(Unmarshaler).UnmarshalCSV(data)
+	}
+
+	if vt.Implements(recordSetterType) {
+		dec := NewDecoder(bytes.NewReader(data))
+		dec.Limits = limits
+		return dec.Decode(v)
+	}
+
+	et := vt.Elem()
+	if et.Kind() == reflect.Slice {
+		elemType := et.Elem()
+		baseType := elemType
+		if elemType.Kind() == reflect.Ptr {
+			baseType = elemType.Elem()
+		}
+		if reflect.PtrTo(baseType).Implements(recordSetterType) {
+			return unmarshalRecordSetterSlice(data, v, et, limits)
+		}
+		if baseType.Kind() == reflect.Struct {
+			return unmarshalStructSlice(data, v, et, limits)
+		}
+		return fmt.Errorf("csv: slice element type %s does not implement RecordSetter", elemType)
+	}
+
+	if et.Kind() == reflect.Struct {
+		return unmarshalStruct(data, v, limits)
+	}
+
+	return fmt.Errorf("csv: cannot unmarshal into %s", vt)
+}
+
+func unmarshalRecordSetterSlice(data []byte, v interface{}, sliceType reflect.Type, limits Limits) error {
+	elemType := sliceType.Elem()
+	isPtr := elemType.Kind() == reflect.Ptr
+	baseType := elemType
+	if isPtr {
+		baseType = elemType.Elem()
+	}
+
+	dec := NewDecoder(bytes.NewReader(data))
+	dec.Limits = limits
+	header, err := dec.readRecord()
+	if err == io.EOF {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	dec.header = header
+	dec.gotHeader = true
+
+	sliceVal := reflect.ValueOf(v).Elem()
+	for {
+		record, err := dec.readRecord()
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+
+		elemPtr := reflect.New(baseType)
+		rs := elemPtr.Interface().(RecordSetter)
+		if err := rs.SetHeader(header); err != nil {
+			return err
+		}
+		if err := rs.SetRecord(record); err != nil {
+			return err
+		}
+
+		if isPtr {
+			sliceVal.Set(reflect.Append(sliceVal, elemPtr))
+		} else {
+			sliceVal.Set(reflect.Append(sliceVal, elemPtr.Elem()))
+		}
+	}
+}
+
+// A Decoder reads and decodes CSV records from an input stream, one at a
+// time, the decoding counterpart to Encoder.
+type Decoder struct {
+	Dialect Dialect
+	Limits  Limits
+
+	r           *bufio.Reader
+	header      []string
+	gotHeader   bool
+	row         int
+	strippedBOM bool
+}
+
+// NewDecoder returns a new Decoder that reads from r using DefaultDialect
+// and DefaultLimits. Callers may adjust the Dialect and Limits fields
+// before the first call to Decode.
+func NewDecoder(r io.Reader) *Decoder {
+	return &Decoder{
+		Dialect: DefaultDialect(),
+		Limits:  DefaultLimits(),
+		r:       bufio.NewReader(r),
+	}
+}
+
+// Decode reads the next record into v, which must implement RecordSetter.
+//
+// The first call to Decode on a given Decoder reads the header row and
+// keeps it; that header is passed to every subsequent SetHeader call. When
+// there are no more records, Decode returns io.EOF.
+func (d *Decoder) Decode(v interface{}) error {
+	rs, ok := v.(RecordSetter)
+	if !ok {
+		return fmt.Errorf("csv: cannot decode into %T", v)
+	}
+
+	if !d.gotHeader {
+		header, err := d.readRecord()
+		if err != nil {
+			return err
+		}
+		d.header = header
+		d.gotHeader = true
+	}
+
+	record, err := d.readRecord()
+	if err != nil {
+		return err
+	}
+
+	if err := rs.SetHeader(d.header); err != nil {
+		return err
+	}
+	return rs.SetRecord(record)
+}
+
+// bomRune is the Unicode byte order mark (U+FEFF), written by Dialect's
+// WriteBOM option.
+const bomRune = '\uFEFF'
+
+// stripBOM discards a leading UTF-8 byte order mark, if present, the first
+// time it is called, so that a file written with Dialect.WriteBOM round-
+// trips through Decoder without leaking it onto the first header cell.
+func (d *Decoder) stripBOM() error {
+	if d.strippedBOM {
+		return nil
+	}
+	d.strippedBOM = true
+
+	r, _, err := d.r.ReadRune()
+	if err != nil {
+		if err == io.EOF {
+			return nil
+		}
+		return err
+	}
+	if r != bomRune {
+		return d.r.UnreadRune()
+	}
+	return nil
+}
+
+// readRecord reads and unquotes the next row, per the Decoder's Dialect,
+// enforcing Limits as it tokenizes so a single hostile row cannot grow
+// field or record buffers without bound.
+func (d *Decoder) readRecord() ([]string, error) {
+	if err := d.stripBOM(); err != nil {
+		return nil, err
+	}
+
+	lim := d.Limits
+	if lim.MaxRecords > 0 && d.row >= lim.MaxRecords {
+		return nil, &LimitError{Kind: RecordCountLimit, Limit: lim.MaxRecords, Row: d.row, Col: -1}
+	}
+
+	var fields []string
+	var field bytes.Buffer
+	quote := d.Dialect.quote()
+	comma := d.Dialect.comma()
+	inQuotes := false
+	sawAny := false
+	recordSize := 0
+	fieldSize := 0
+
+	checkField := func() error {
+		if lim.MaxFieldSize > 0 && fieldSize > lim.MaxFieldSize {
+			return &LimitError{Kind: FieldSizeLimit, Limit: lim.MaxFieldSize, Row: d.row, Col: len(fields)}
+		}
+		return nil
+	}
+	endField := func() error {
+		fields = append(fields, field.String())
+		field.Reset()
+		fieldSize = 0
+		if lim.MaxFieldCount > 0 && len(fields) > lim.MaxFieldCount {
+			return &LimitError{Kind: FieldCountLimit, Limit: lim.MaxFieldCount, Row: d.row, Col: len(fields) - 1}
+		}
+		return nil
+	}
+	endRecord := func() ([]string, error) {
+		if err := endField(); err != nil {
+			return nil, err
+		}
+		d.row++
+		return fields, nil
+	}
+
+	for {
+		r, n, err := d.r.ReadRune()
+		if err != nil {
+			if err == io.EOF {
+				if !sawAny {
+					return nil, io.EOF
+				}
+				return endRecord()
+			}
+			return nil, err
+		}
+		if !sawAny && lim.MaxRecords > 0 && d.row >= lim.MaxRecords {
+			return nil, &LimitError{Kind: RecordCountLimit, Limit: lim.MaxRecords, Row: d.row, Col: -1}
+		}
+		sawAny = true
+
+		recordSize += n
+		if lim.MaxRecordSize > 0 && recordSize > lim.MaxRecordSize {
+			return nil, &LimitError{Kind: RecordSizeLimit, Limit: lim.MaxRecordSize, Row: d.row, Col: len(fields)}
+		}
+
+		if inQuotes {
+			if r == quote {
+				next, nn, err := d.r.ReadRune()
+				if err == nil && next == quote {
+					recordSize += nn
+					fieldSize += nn
+					if err := checkField(); err != nil {
+						return nil, err
+					}
+					field.WriteRune(quote)
+					continue
+				}
+				if err == nil {
+					d.r.UnreadRune()
+				}
+				inQuotes = false
+				continue
+			}
+			fieldSize += n
+			if err := checkField(); err != nil {
+				return nil, err
+			}
+			field.WriteRune(r)
+			continue
+		}
+
+		switch r {
+		case quote:
+			if field.Len() == 0 {
+				inQuotes = true
+				continue
+			}
+			fieldSize += n
+			if err := checkField(); err != nil {
+				return nil, err
+			}
+			field.WriteRune(r)
+		case comma:
+			if err := endField(); err != nil {
+				return nil, err
+			}
+		case '\r':
+			if next, _, err := d.r.ReadRune(); err == nil && next != '\n' {
+				d.r.UnreadRune()
+			}
+			return endRecord()
+		case '\n':
+			return endRecord()
+		default:
+			fieldSize += n
+			if err := checkField(); err != nil {
+				return nil, err
+			}
+			field.WriteRune(r)
+		}
+	}
+}
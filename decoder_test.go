@@ -0,0 +1,50 @@
+// Copyright (c) 2016, Ben Morgan. All rights reserved.
+// Use of this source code is governed by an MIT license
+// that can be found in the LICENSE file.
+
+package csv_test
+
+import (
+	"fmt"
+	"testing"
+
+	csv "goulash/csv"
+)
+
+type kv struct {
+	Key, Val string
+}
+
+func (r *kv) Header() []string { return []string{"key", "value"} }
+func (r *kv) Record() []string { return []string{r.Key, r.Val} }
+
+func (r *kv) SetHeader([]string) error { return nil }
+func (r *kv) SetRecord(rec []string) error {
+	if len(rec) != 2 {
+		return fmt.Errorf("want 2 fields, got %d", len(rec))
+	}
+	r.Key, r.Val = rec[0], rec[1]
+	return nil
+}
+
+func TestUnmarshalRecordSetterSlice(t *testing.T) {
+	in := []*kv{{"a", "1"}, {"b", "2"}}
+	bs, err := csv.Marshal(in)
+	if err != nil {
+		t.Fatalf("Marshal: %v", err)
+	}
+
+	var out []*kv
+	if err := csv.Unmarshal(bs, &out); err != nil {
+		t.Fatalf("Unmarshal: %v", err)
+	}
+
+	if len(out) != len(in) {
+		t.Fatalf("got %d elements, want %d", len(out), len(in))
+	}
+	for i := range in {
+		if out[i].Key != in[i].Key || out[i].Val != in[i].Val {
+			t.Errorf("element %d: got %+v, want %+v", i, out[i], in[i])
+		}
+	}
+}
@@ -0,0 +1,66 @@
+// Copyright (c) 2016, Ben Morgan. All rights reserved.
+// Use of this source code is governed by an MIT license
+// that can be found in the LICENSE file.
+
+package csv
+
+// A Dialect describes the field and line conventions an Encoder (or Decoder)
+// should use when reading or writing CSV.
+//
+// The zero value is usable: each zero field falls back to the default noted
+// on it. DefaultDialect returns those defaults filled in explicitly.
+type Dialect struct {
+	// Comma is the field delimiter. It defaults to ',' if zero.
+	Comma rune
+
+	// Quote is the character used to quote fields that contain special
+	// characters. It defaults to '"' if zero.
+	Quote rune
+
+	// LineTerminator is written after each record. It is ignored if UseCRLF
+	// is true. It defaults to "\n" if empty.
+	LineTerminator string
+
+	// UseCRLF forces "\r\n" as the line terminator, overriding LineTerminator.
+	UseCRLF bool
+
+	// AlwaysQuote, if true, quotes every field regardless of its contents.
+	AlwaysQuote bool
+
+	// WriteBOM, if true, writes a UTF-8 byte order mark before the first
+	// record.
+	WriteBOM bool
+}
+
+// DefaultDialect returns the standard comma-separated, LF-terminated dialect.
+func DefaultDialect() Dialect {
+	return Dialect{
+		Comma:          ',',
+		Quote:          '"',
+		LineTerminator: "\n",
+	}
+}
+
+func (d Dialect) comma() rune {
+	if d.Comma == 0 {
+		return ','
+	}
+	return d.Comma
+}
+
+func (d Dialect) quote() rune {
+	if d.Quote == 0 {
+		return '"'
+	}
+	return d.Quote
+}
+
+func (d Dialect) terminator() string {
+	if d.UseCRLF {
+		return "\r\n"
+	}
+	if d.LineTerminator == "" {
+		return "\n"
+	}
+	return d.LineTerminator
+}
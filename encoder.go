@@ -0,0 +1,158 @@
+// Copyright (c) 2016, Ben Morgan. All rights reserved.
+// Use of this source code is governed by an MIT license
+// that can be found in the LICENSE file.
+
+package csv
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"reflect"
+	"strings"
+)
+
+// A TypeEncoderFunc renders the header and record for a single value of a
+// type registered with Encoder.RegisterType.
+type TypeEncoderFunc func(v interface{}) (header []string, record []string, err error)
+
+// An Encoder writes CSV records to an output stream, one at a time, instead
+// of buffering an entire document in memory like Marshal does.
+type Encoder struct {
+	Dialect Dialect
+
+	w         *bufio.Writer
+	types     map[reflect.Type]TypeEncoderFunc
+	wroteBOM  bool
+	wroteHead bool
+}
+
+// NewEncoder returns a new Encoder that writes to w using DefaultDialect.
+// Callers may adjust the Dialect field before the first call to Encode.
+func NewEncoder(w io.Writer) *Encoder {
+	return &Encoder{
+		Dialect: DefaultDialect(),
+		w:       bufio.NewWriter(w),
+	}
+}
+
+// RegisterType teaches e to encode values of type t with fn, so that types
+// which cannot be made to implement Marshaler or Recorder - such as types
+// from other packages, like net.IP - can still be passed to Encode. fn is
+// consulted before the Marshaler and Recorder interface checks.
+//
+// As with Recorder, only the header from the first call to Encode is ever
+// written; mixing values whose headers differ (including across
+// registered types) on one Encoder will not emit a second header.
+func (e *Encoder) RegisterType(t reflect.Type, fn TypeEncoderFunc) {
+	if e.types == nil {
+		e.types = make(map[reflect.Type]TypeEncoderFunc)
+	}
+	e.types[t] = fn
+}
+
+// Encode writes v to the stream. v must be of a type registered with
+// RegisterType, or implement Marshaler or Recorder.
+//
+// The first time Encode writes a header - from a Recorder, or from a
+// registered TypeEncoderFunc - it is written before the record. Subsequent
+// calls only write the record.
+func (e *Encoder) Encode(v interface{}) error {
+	if err := e.writeBOM(); err != nil {
+		return err
+	}
+
+	if fn, ok := e.types[reflect.TypeOf(v)]; ok {
+		header, record, err := fn(v)
+		if err != nil {
+			return err
+		}
+		return e.encodeHeaderAndRecord(header, record)
+	}
+
+	if m, ok := v.(Marshaler); ok {
+		bs, err := m.MarshalCSV()
+		if err != nil {
+			return err
+		}
+		_, err = e.w.Write(bs)
+		return err
+	}
+
+	r, ok := v.(Recorder)
+	if !ok {
+		return fmt.Errorf("csv: cannot encode %T", v)
+	}
+	return e.encodeHeaderAndRecord(r.Header(), r.Record())
+}
+
+func (e *Encoder) encodeHeaderAndRecord(header, record []string) error {
+	if !e.wroteHead {
+		if err := e.writeRecord(header); err != nil {
+			return err
+		}
+		e.wroteHead = true
+	}
+	return e.writeRecord(record)
+}
+
+// Flush writes any buffered data to the underlying io.Writer.
+func (e *Encoder) Flush() error {
+	return e.w.Flush()
+}
+
+func (e *Encoder) writeBOM() error {
+	if !e.Dialect.WriteBOM || e.wroteBOM {
+		return nil
+	}
+	e.wroteBOM = true
+	_, err := e.w.WriteString("\xEF\xBB\xBF")
+	return err
+}
+
+func (e *Encoder) writeRecord(fields []string) error {
+	comma := e.Dialect.comma()
+	for i, field := range fields {
+		if i > 0 {
+			if _, err := e.w.WriteRune(comma); err != nil {
+				return err
+			}
+		}
+		if err := e.writeField(field); err != nil {
+			return err
+		}
+	}
+	_, err := e.w.WriteString(e.Dialect.terminator())
+	return err
+}
+
+func (e *Encoder) writeField(s string) error {
+	if !e.Dialect.AlwaysQuote && !e.needsQuoting(s) {
+		_, err := e.w.WriteString(s)
+		return err
+	}
+
+	quote := e.Dialect.quote()
+	if _, err := e.w.WriteRune(quote); err != nil {
+		return err
+	}
+	for _, r := range s {
+		if r == quote {
+			if _, err := e.w.WriteRune(quote); err != nil {
+				return err
+			}
+		}
+		if _, err := e.w.WriteRune(r); err != nil {
+			return err
+		}
+	}
+	_, err := e.w.WriteRune(quote)
+	return err
+}
+
+// needsQuoting reports whether s must be quoted per RFC 4180: it contains
+// the delimiter, the quote character, or a CR or LF.
+func (e *Encoder) needsQuoting(s string) bool {
+	special := string(e.Dialect.comma()) + string(e.Dialect.quote()) + "\r\n"
+	return strings.ContainsAny(s, special)
+}
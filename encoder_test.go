@@ -0,0 +1,97 @@
+// Copyright (c) 2016, Ben Morgan. All rights reserved.
+// Use of this source code is governed by an MIT license
+// that can be found in the LICENSE file.
+
+package csv_test
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	csv "goulash/csv"
+)
+
+type recorder struct {
+	h, r []string
+}
+
+func (x *recorder) SetHeader(h []string) error { x.h = h; return nil }
+func (x *recorder) SetRecord(r []string) error { x.r = r; return nil }
+
+func TestEncodeDecodeRoundTrip(t *testing.T) {
+	cases := []struct {
+		name   string
+		record []string
+	}{
+		{"plain", []string{"plain", "value"}},
+		{"comma", []string{"with,comma", "value"}},
+		{"quote", []string{`with "quote"`, "value"}},
+		{"newline", []string{"with\nnewline", "value"}},
+		{"cr", []string{"with\rcr", "value"}},
+		{"crlf", []string{"with\r\ncrlf", "value"}},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			var buf bytes.Buffer
+			enc := csv.NewEncoder(&buf)
+			header := []string{"a", "b"}
+			if err := enc.Encode(csv.NewRecorder(header, tc.record)); err != nil {
+				t.Fatalf("Encode: %v", err)
+			}
+			if err := enc.Flush(); err != nil {
+				t.Fatalf("Flush: %v", err)
+			}
+
+			dec := csv.NewDecoder(bytes.NewReader(buf.Bytes()))
+			var got recorder
+			if err := dec.Decode(&got); err != nil {
+				t.Fatalf("Decode: %v", err)
+			}
+			if len(got.r) != len(tc.record) || got.r[0] != tc.record[0] || got.r[1] != tc.record[1] {
+				t.Errorf("round trip through %q: got %q, want %q", buf.String(), got.r, tc.record)
+			}
+		})
+	}
+}
+
+func TestEncoderUseCRLF(t *testing.T) {
+	var buf bytes.Buffer
+	enc := csv.NewEncoder(&buf)
+	enc.Dialect.UseCRLF = true
+	if err := enc.Encode(csv.NewRecorder([]string{"a"}, []string{"1"})); err != nil {
+		t.Fatalf("Encode: %v", err)
+	}
+	if err := enc.Flush(); err != nil {
+		t.Fatalf("Flush: %v", err)
+	}
+	if got, want := buf.String(), "a\r\n1\r\n"; got != want {
+		t.Errorf("got %q, want %q", got, want)
+	}
+}
+
+func TestBOMRoundTrip(t *testing.T) {
+	var buf bytes.Buffer
+	enc := csv.NewEncoder(&buf)
+	enc.Dialect.WriteBOM = true
+	if err := enc.Encode(csv.NewRecorder([]string{"name"}, []string{"Alice"})); err != nil {
+		t.Fatalf("Encode: %v", err)
+	}
+	if err := enc.Flush(); err != nil {
+		t.Fatalf("Flush: %v", err)
+	}
+
+	if !strings.HasPrefix(buf.String(), "\ufeff") {
+		t.Fatalf("expected encoded output to start with a BOM, got %q", buf.String())
+	}
+
+	dec := csv.NewDecoder(bytes.NewReader(buf.Bytes()))
+	var got recorder
+	if err := dec.Decode(&got); err != nil {
+		t.Fatalf("Decode: %v", err)
+	}
+	if len(got.h) == 0 || got.h[0] != "name" {
+		t.Errorf("BOM leaked into header: got %q", got.h)
+	}
+}
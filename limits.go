@@ -0,0 +1,79 @@
+// Copyright (c) 2016, Ben Morgan. All rights reserved.
+// Use of this source code is governed by an MIT license
+// that can be found in the LICENSE file.
+
+package csv
+
+import "fmt"
+
+// Limits bound how much memory a Decoder will use while reading a single
+// document, so that hostile or malformed input (such as a multi-gigabyte
+// unterminated quoted field) cannot exhaust memory.
+type Limits struct {
+	// MaxRecordSize is the maximum number of bytes in a single row,
+	// including quoting.
+	MaxRecordSize int
+
+	// MaxFieldSize is the maximum number of bytes in a single field.
+	MaxFieldSize int
+
+	// MaxRecords is the maximum number of rows, including the header, a
+	// document may contain.
+	MaxRecords int
+
+	// MaxFieldCount is the maximum number of columns a single row may
+	// contain.
+	MaxFieldCount int
+}
+
+// DefaultLimits returns generous limits suitable for trusted input: 1<<20
+// bytes per field, 1<<20 bytes per record, 1<<20 records, and 1<<20 fields
+// per record.
+func DefaultLimits() Limits {
+	return Limits{
+		MaxRecordSize: 1 << 20,
+		MaxFieldSize:  1 << 20,
+		MaxRecords:    1 << 20,
+		MaxFieldCount: 1 << 20,
+	}
+}
+
+// A LimitKind identifies which Limits field a LimitError exceeded.
+type LimitKind int
+
+const (
+	_ LimitKind = iota
+	RecordSizeLimit
+	FieldSizeLimit
+	RecordCountLimit
+	FieldCountLimit
+)
+
+func (k LimitKind) String() string {
+	switch k {
+	case RecordSizeLimit:
+		return "MaxRecordSize"
+	case FieldSizeLimit:
+		return "MaxFieldSize"
+	case RecordCountLimit:
+		return "MaxRecords"
+	case FieldCountLimit:
+		return "MaxFieldCount"
+	default:
+		return "unknown limit"
+	}
+}
+
+// A LimitError reports that decoding exceeded one of a Decoder's Limits.
+// Row and Col are zero-indexed; Col is -1 when the limit applies to the
+// row as a whole rather than a specific field.
+type LimitError struct {
+	Kind  LimitKind
+	Limit int
+	Row   int
+	Col   int
+}
+
+func (e *LimitError) Error() string {
+	return fmt.Sprintf("csv: %s (%d) exceeded at row %d, column %d", e.Kind, e.Limit, e.Row, e.Col)
+}
@@ -0,0 +1,79 @@
+// Copyright (c) 2016, Ben Morgan. All rights reserved.
+// Use of this source code is governed by an MIT license
+// that can be found in the LICENSE file.
+
+package csv_test
+
+import (
+	"bytes"
+	"errors"
+	"strings"
+	"testing"
+
+	csv "goulash/csv"
+)
+
+func TestDecoderLimits(t *testing.T) {
+	cases := []struct {
+		name   string
+		data   string
+		limits func(l *csv.Limits)
+		want   csv.LimitKind
+	}{
+		{
+			name: "MaxFieldSize",
+			data: "a,b\n" + strings.Repeat("x", 20) + ",2\n",
+			limits: func(l *csv.Limits) {
+				l.MaxFieldSize = 5
+			},
+			want: csv.FieldSizeLimit,
+		},
+		{
+			name: "MaxRecordSize",
+			data: "a,b\n" + strings.Repeat("x", 20) + "," + strings.Repeat("y", 20) + "\n",
+			limits: func(l *csv.Limits) {
+				l.MaxRecordSize = 10
+			},
+			want: csv.RecordSizeLimit,
+		},
+		{
+			name: "MaxRecords",
+			data: "a,b\n1,2\n3,4\n5,6\n",
+			limits: func(l *csv.Limits) {
+				l.MaxRecords = 2
+			},
+			want: csv.RecordCountLimit,
+		},
+		{
+			name: "MaxFieldCount",
+			data: "a,b,c\n1,2,3\n",
+			limits: func(l *csv.Limits) {
+				l.MaxFieldCount = 2
+			},
+			want: csv.FieldCountLimit,
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			dec := csv.NewDecoder(bytes.NewReader([]byte(tc.data)))
+			tc.limits(&dec.Limits)
+
+			var err error
+			for i := 0; i < 4; i++ {
+				var got recorder
+				if err = dec.Decode(&got); err != nil {
+					break
+				}
+			}
+
+			var limitErr *csv.LimitError
+			if !errors.As(err, &limitErr) {
+				t.Fatalf("got err %v, want a *LimitError", err)
+			}
+			if limitErr.Kind != tc.want {
+				t.Errorf("got Kind %v, want %v", limitErr.Kind, tc.want)
+			}
+		})
+	}
+}
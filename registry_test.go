@@ -0,0 +1,42 @@
+// Copyright (c) 2016, Ben Morgan. All rights reserved.
+// Use of this source code is governed by an MIT license
+// that can be found in the LICENSE file.
+
+package csv_test
+
+import (
+	"bytes"
+	"reflect"
+	"testing"
+
+	csv "goulash/csv"
+)
+
+// dual implements Recorder, so that TestRegisterTypeOrdering can confirm a
+// registered TypeEncoderFunc is consulted before the Recorder check.
+type dual struct {
+	V string
+}
+
+func (d dual) Header() []string { return []string{"via-recorder"} }
+func (d dual) Record() []string { return []string{"recorder:" + d.V} }
+
+func TestRegisterTypeOrdering(t *testing.T) {
+	var buf bytes.Buffer
+	enc := csv.NewEncoder(&buf)
+	enc.RegisterType(reflect.TypeOf(dual{}), func(v interface{}) ([]string, []string, error) {
+		d := v.(dual)
+		return []string{"via-registry"}, []string{"registry:" + d.V}, nil
+	})
+
+	if err := enc.Encode(dual{V: "x"}); err != nil {
+		t.Fatalf("Encode: %v", err)
+	}
+	if err := enc.Flush(); err != nil {
+		t.Fatalf("Flush: %v", err)
+	}
+
+	if want := "via-registry\nregistry:x\n"; buf.String() != want {
+		t.Errorf("got %q, want %q", buf.String(), want)
+	}
+}
@@ -0,0 +1,429 @@
+// Copyright (c) 2016, Ben Morgan. All rights reserved.
+// Use of this source code is governed by an MIT license
+// that can be found in the LICENSE file.
+
+package csv
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"reflect"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Marshal and Unmarshal also accept plain structs, and slices of structs (or
+// of pointers to structs), without requiring the type to implement Recorder
+// or RecordSetter; Marshal also accepts arrays of them. Fields are mapped to CSV columns using the
+// "csv" struct tag, similar to encoding/json:
+//
+//	type Person struct {
+//		Name    string    `csv:"name"`
+//		Age     int       `csv:"age,omitempty"`
+//		Secret  string     `csv:"-"`
+//		Created time.Time `csv:"created,layout=2006-01-02"`
+//		Address `csv:",inline"`
+//	}
+//
+// A field with no tag uses its Go field name as the column name. A tag of
+// "-" omits the field entirely. The "omitempty" option leaves the column
+// blank when the field is false, 0, a nil pointer or interface, a zero-length
+// string, slice, map or array, or a zero time.Time; other struct values are
+// never treated as empty. The "inline" option, valid
+// only on an embedded struct field, flattens that struct's own columns into
+// the parent's header and record instead of giving it a single column.
+//
+// MarshalerFunc overrides may be registered per reflect.Type with
+// RegisterMarshalerFunc for types that need custom formatting, such as
+// types from other packages that Marshal cannot teach to implement
+// Recorder. time.Time is supported out of the box via the "layout" tag
+// option (RFC 3339 if omitted).
+
+// A MarshalerFunc formats a single field value as a string when using
+// struct-tag based marshaling.
+type MarshalerFunc func(v interface{}) (string, error)
+
+var fieldMarshalers sync.Map // map[reflect.Type]MarshalerFunc
+
+// RegisterMarshalerFunc registers fn to format every field of type t
+// encountered during struct-tag based marshaling.
+func RegisterMarshalerFunc(t reflect.Type, fn MarshalerFunc) {
+	fieldMarshalers.Store(t, fn)
+}
+
+var timeType = reflect.TypeOf(time.Time{})
+
+type fieldInfo struct {
+	index     []int
+	name      string
+	omitempty bool
+	layout    string
+}
+
+// fieldCache holds the []fieldInfo for each struct type Marshal or Unmarshal
+// has already walked, so repeated calls for the same type skip the
+// reflection walk.
+var fieldCache sync.Map // map[reflect.Type][]fieldInfo
+
+func cachedFields(t reflect.Type) []fieldInfo {
+	if v, ok := fieldCache.Load(t); ok {
+		return v.([]fieldInfo)
+	}
+	fields := buildFields(t, nil)
+	v, _ := fieldCache.LoadOrStore(t, fields)
+	return v.([]fieldInfo)
+}
+
+func buildFields(t reflect.Type, index []int) []fieldInfo {
+	var fields []fieldInfo
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		if f.PkgPath != "" && !f.Anonymous {
+			continue // unexported
+		}
+
+		tag := f.Tag.Get("csv")
+		if tag == "-" {
+			continue
+		}
+		name, opts := parseTag(tag)
+
+		idx := make([]int, len(index)+1)
+		copy(idx, index)
+		idx[len(index)] = i
+
+		if f.Anonymous && opts.inline {
+			ft := f.Type
+			if ft.Kind() == reflect.Ptr {
+				ft = ft.Elem()
+			}
+			fields = append(fields, buildFields(ft, idx)...)
+			continue
+		}
+
+		if name == "" {
+			name = f.Name
+		}
+		fields = append(fields, fieldInfo{
+			index:     idx,
+			name:      name,
+			omitempty: opts.omitempty,
+			layout:    opts.layout,
+		})
+	}
+	return fields
+}
+
+type tagOptions struct {
+	omitempty bool
+	inline    bool
+	layout    string
+}
+
+func parseTag(tag string) (name string, opts tagOptions) {
+	if tag == "" {
+		return "", opts
+	}
+	parts := strings.Split(tag, ",")
+	name = parts[0]
+	for _, p := range parts[1:] {
+		switch {
+		case p == "omitempty":
+			opts.omitempty = true
+		case p == "inline":
+			opts.inline = true
+		case strings.HasPrefix(p, "layout="):
+			opts.layout = strings.TrimPrefix(p, "layout=")
+		}
+	}
+	return name, opts
+}
+
+// fieldByIndex walks index into v, allocating nil pointers to embedded
+// structs along the way.
+func fieldByIndex(v reflect.Value, index []int) reflect.Value {
+	for i, x := range index {
+		if i > 0 && v.Kind() == reflect.Ptr {
+			if v.IsNil() {
+				v.Set(reflect.New(v.Type().Elem()))
+			}
+			v = v.Elem()
+		}
+		v = v.Field(x)
+	}
+	return v
+}
+
+// fieldByIndexForRead is fieldByIndex for the read-only marshal path, where
+// v may not be addressable (e.g. a plain struct passed by value to
+// Marshal). It never mutates v, and reports ok=false if index passes
+// through a nil embedded pointer, since there is then nothing to read.
+func fieldByIndexForRead(v reflect.Value, index []int) (fv reflect.Value, ok bool) {
+	for i, x := range index {
+		if i > 0 && v.Kind() == reflect.Ptr {
+			if v.IsNil() {
+				return reflect.Value{}, false
+			}
+			v = v.Elem()
+		}
+		v = v.Field(x)
+	}
+	return v, true
+}
+
+func formatField(v reflect.Value, layout string) (string, error) {
+	if v.Kind() == reflect.Ptr {
+		if v.IsNil() {
+			return "", nil
+		}
+		v = v.Elem()
+	}
+
+	if fn, ok := fieldMarshalers.Load(v.Type()); ok {
+		return fn.(MarshalerFunc)(v.Interface())
+	}
+
+	if v.Type() == timeType {
+		l := layout
+		if l == "" {
+			l = time.RFC3339
+		}
+		return v.Interface().(time.Time).Format(l), nil
+	}
+
+	return fmt.Sprint(v.Interface()), nil
+}
+
+func isEmptyValue(v reflect.Value) bool {
+	switch v.Kind() {
+	case reflect.Array, reflect.Map, reflect.Slice, reflect.String:
+		return v.Len() == 0
+	case reflect.Bool:
+		return !v.Bool()
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return v.Int() == 0
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		return v.Uint() == 0
+	case reflect.Float32, reflect.Float64:
+		return v.Float() == 0
+	case reflect.Interface, reflect.Ptr:
+		return v.IsNil()
+	case reflect.Struct:
+		if v.Type() == timeType {
+			return v.Interface().(time.Time).IsZero()
+		}
+	}
+	return false
+}
+
+func structHeaderAndRecord(v reflect.Value) ([]string, []string, error) {
+	fields := cachedFields(v.Type())
+	header := make([]string, len(fields))
+	record := make([]string, len(fields))
+	for i, f := range fields {
+		header[i] = f.name
+		fv, ok := fieldByIndexForRead(v, f.index)
+		if !ok {
+			continue // nil embedded pointer along the path: leave blank
+		}
+		if f.omitempty && isEmptyValue(fv) {
+			continue
+		}
+		s, err := formatField(fv, f.layout)
+		if err != nil {
+			return nil, nil, fmt.Errorf("csv: field %s: %w", f.name, err)
+		}
+		record[i] = s
+	}
+	return header, record, nil
+}
+
+func marshalStruct(v interface{}) ([]byte, error) {
+	header, record, err := structHeaderAndRecord(reflect.ValueOf(v))
+	if err != nil {
+		return nil, err
+	}
+
+	var buf bytes.Buffer
+	enc := NewEncoder(&buf)
+	if err := enc.Encode(NewRecorder(header, record)); err != nil {
+		return nil, err
+	}
+	if err := enc.Flush(); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+func marshalStructSlice(v interface{}) ([]byte, error) {
+	vv := reflect.ValueOf(v)
+	n := vv.Len()
+	if n == 0 {
+		return nil, errors.New("csv: no data")
+	}
+
+	var buf bytes.Buffer
+	enc := NewEncoder(&buf)
+	for i := 0; i < n; i++ {
+		elem := vv.Index(i)
+		if elem.Kind() == reflect.Ptr {
+			if elem.IsNil() {
+				return nil, fmt.Errorf("csv: nil %s in slice", elem.Type())
+			}
+			elem = elem.Elem()
+		}
+		header, record, err := structHeaderAndRecord(elem)
+		if err != nil {
+			return nil, err
+		}
+		if err := enc.Encode(NewRecorder(header, record)); err != nil {
+			return nil, err
+		}
+	}
+	if err := enc.Flush(); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+func setFieldValue(fv reflect.Value, s string, layout string) error {
+	if fv.Kind() == reflect.Ptr {
+		if s == "" {
+			return nil
+		}
+		if fv.IsNil() {
+			fv.Set(reflect.New(fv.Type().Elem()))
+		}
+		return setFieldValue(fv.Elem(), s, layout)
+	}
+
+	if s == "" {
+		return nil
+	}
+
+	if fv.Type() == timeType {
+		l := layout
+		if l == "" {
+			l = time.RFC3339
+		}
+		t, err := time.Parse(l, s)
+		if err != nil {
+			return err
+		}
+		fv.Set(reflect.ValueOf(t))
+		return nil
+	}
+
+	switch fv.Kind() {
+	case reflect.String:
+		fv.SetString(s)
+	case reflect.Bool:
+		b, err := strconv.ParseBool(s)
+		if err != nil {
+			return err
+		}
+		fv.SetBool(b)
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		n, err := strconv.ParseInt(s, 10, 64)
+		if err != nil {
+			return err
+		}
+		fv.SetInt(n)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		n, err := strconv.ParseUint(s, 10, 64)
+		if err != nil {
+			return err
+		}
+		fv.SetUint(n)
+	case reflect.Float32, reflect.Float64:
+		n, err := strconv.ParseFloat(s, 64)
+		if err != nil {
+			return err
+		}
+		fv.SetFloat(n)
+	default:
+		return fmt.Errorf("unsupported field type %s", fv.Type())
+	}
+	return nil
+}
+
+func setStructFields(rv reflect.Value, header, record []string) error {
+	fields := cachedFields(rv.Type())
+	byName := make(map[string]fieldInfo, len(fields))
+	for _, f := range fields {
+		byName[f.name] = f
+	}
+
+	for i, col := range header {
+		if i >= len(record) {
+			break
+		}
+		f, ok := byName[col]
+		if !ok {
+			continue
+		}
+		if err := setFieldValue(fieldByIndex(rv, f.index), record[i], f.layout); err != nil {
+			return fmt.Errorf("csv: field %s: %w", col, err)
+		}
+	}
+	return nil
+}
+
+func unmarshalStruct(data []byte, v interface{}, limits Limits) error {
+	dec := NewDecoder(bytes.NewReader(data))
+	dec.Limits = limits
+	header, err := dec.readRecord()
+	if err != nil {
+		return err
+	}
+	record, err := dec.readRecord()
+	if err != nil {
+		return err
+	}
+	return setStructFields(reflect.ValueOf(v).Elem(), header, record)
+}
+
+func unmarshalStructSlice(data []byte, v interface{}, sliceType reflect.Type, limits Limits) error {
+	elemType := sliceType.Elem()
+	isPtr := elemType.Kind() == reflect.Ptr
+	baseType := elemType
+	if isPtr {
+		baseType = elemType.Elem()
+	}
+
+	dec := NewDecoder(bytes.NewReader(data))
+	dec.Limits = limits
+	header, err := dec.readRecord()
+	if err == io.EOF {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	sliceVal := reflect.ValueOf(v).Elem()
+	for {
+		record, err := dec.readRecord()
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+
+		elemPtr := reflect.New(baseType)
+		if err := setStructFields(elemPtr.Elem(), header, record); err != nil {
+			return err
+		}
+
+		if isPtr {
+			sliceVal.Set(reflect.Append(sliceVal, elemPtr))
+		} else {
+			sliceVal.Set(reflect.Append(sliceVal, elemPtr.Elem()))
+		}
+	}
+}
@@ -0,0 +1,131 @@
+// Copyright (c) 2016, Ben Morgan. All rights reserved.
+// Use of this source code is governed by an MIT license
+// that can be found in the LICENSE file.
+
+package csv_test
+
+import (
+	"fmt"
+	"reflect"
+	"testing"
+	"time"
+
+	csv "goulash/csv"
+)
+
+type taggedThing struct {
+	ID     int    `csv:"id"`
+	Secret string `csv:"-"`
+	Name   string `csv:"full_name"`
+	Age    int    `csv:"age,omitempty"`
+}
+
+func TestStructTagNameAndSkipAndOmitempty(t *testing.T) {
+	v := taggedThing{ID: 1, Secret: "hide me", Name: "Alice", Age: 0}
+	bs, err := csv.Marshal(v)
+	if err != nil {
+		t.Fatalf("Marshal: %v", err)
+	}
+	if want := "id,full_name,age\n1,Alice,\n"; string(bs) != want {
+		t.Errorf("got %q, want %q", bs, want)
+	}
+}
+
+type address struct {
+	City string `csv:"city"`
+}
+
+type personValueInline struct {
+	Name    string `csv:"name"`
+	address `csv:",inline"`
+}
+
+type personPtrInline struct {
+	Name     string `csv:"name"`
+	*address `csv:",inline"`
+}
+
+func TestInlineValueEmbed(t *testing.T) {
+	v := personValueInline{Name: "A", address: address{City: "NYC"}}
+	bs, err := csv.Marshal(v)
+	if err != nil {
+		t.Fatalf("Marshal: %v", err)
+	}
+	if want := "name,city\nA,NYC\n"; string(bs) != want {
+		t.Errorf("got %q, want %q", bs, want)
+	}
+}
+
+func TestInlineNilPointerEmbed(t *testing.T) {
+	v := personPtrInline{Name: "B"}
+	bs, err := csv.Marshal(v)
+	if err != nil {
+		t.Fatalf("Marshal: %v", err)
+	}
+	if want := "name,city\nB,\n"; string(bs) != want {
+		t.Errorf("got %q, want %q", bs, want)
+	}
+}
+
+func TestInlineNonNilPointerEmbed(t *testing.T) {
+	v := personPtrInline{Name: "C", address: &address{City: "LA"}}
+	bs, err := csv.Marshal(v)
+	if err != nil {
+		t.Fatalf("Marshal: %v", err)
+	}
+	if want := "name,city\nC,LA\n"; string(bs) != want {
+		t.Errorf("got %q, want %q", bs, want)
+	}
+}
+
+type event struct {
+	Name  string    `csv:"name"`
+	When  time.Time `csv:"when,layout=2006-01-02"`
+	Ended time.Time `csv:"ended,omitempty,layout=2006-01-02"`
+}
+
+func TestLayoutRoundTrip(t *testing.T) {
+	events := []event{
+		{Name: "a", When: time.Date(2024, 1, 2, 0, 0, 0, 0, time.UTC)},
+	}
+	bs, err := csv.Marshal(events)
+	if err != nil {
+		t.Fatalf("Marshal: %v", err)
+	}
+	if want := "name,when,ended\na,2024-01-02,\n"; string(bs) != want {
+		t.Fatalf("got %q, want %q", bs, want)
+	}
+
+	var out []event
+	if err := csv.Unmarshal(bs, &out); err != nil {
+		t.Fatalf("Unmarshal: %v", err)
+	}
+	if !out[0].When.Equal(events[0].When) {
+		t.Errorf("When: got %v, want %v", out[0].When, events[0].When)
+	}
+	if !out[0].Ended.IsZero() {
+		t.Errorf("Ended: got %v, want zero value", out[0].Ended)
+	}
+}
+
+type money int
+
+type item struct {
+	Name  string `csv:"name"`
+	Price money  `csv:"price"`
+}
+
+func TestRegisterMarshalerFunc(t *testing.T) {
+	csv.RegisterMarshalerFunc(reflect.TypeOf(money(0)), func(v interface{}) (string, error) {
+		m := v.(money)
+		return fmt.Sprintf("$%d.%02d", m/100, m%100), nil
+	})
+
+	bs, err := csv.Marshal(item{Name: "Book", Price: 1999})
+	if err != nil {
+		t.Fatalf("Marshal: %v", err)
+	}
+	if want := "name,price\nBook,$19.99\n"; string(bs) != want {
+		t.Errorf("got %q, want %q", bs, want)
+	}
+}